@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"gcd-labs/proto"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+type batchPair struct {
+	A uint64 `json:"a"`
+	B uint64 `json:"b"`
+}
+
+type batchResult struct {
+	Result uint64 `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// registerBatchRoute wires POST /gcd/batch, a newline-delimited-JSON endpoint
+// that tees the request body into the ComputeStream RPC and streams results
+// back as they're computed, so high-throughput clients can amortize
+// connection setup over many computations instead of one HTTP request per
+// pair.
+func registerBatchRoute(mux *http.ServeMux, conn *grpc.ClientConn) {
+	client := proto.NewGCDServiceClient(conn)
+
+	mux.HandleFunc("/gcd/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		stream, err := client.ComputeStream(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		decodeErr := make(chan error, 1)
+		go func() {
+			defer close(decodeErr)
+			dec := json.NewDecoder(r.Body)
+			for {
+				var pair batchPair
+				if err := dec.Decode(&pair); err != nil {
+					if err != io.EOF {
+						decodeErr <- err
+					}
+					break
+				}
+				if err := stream.Send(&proto.GCDRequest{A: pair.A, B: pair.B}); err != nil {
+					break
+				}
+			}
+			_ = stream.CloseSend()
+		}()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher, _ := w.(http.Flusher)
+		enc := json.NewEncoder(w)
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				if err := <-decodeErr; err != nil {
+					_ = enc.Encode(batchResult{Error: "malformed request body: " + err.Error()})
+				}
+				return
+			}
+			if err != nil {
+				_ = enc.Encode(batchResult{Error: err.Error()})
+				return
+			}
+			if resp.Error != "" {
+				_ = enc.Encode(batchResult{Error: resp.Error})
+			} else {
+				_ = enc.Encode(batchResult{Result: resp.Result})
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	})
+}
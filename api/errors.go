@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// withRequestTimeout bounds every request reaching next with a deadline, so a
+// slow or wedged gcd-service can't hold a gateway connection open forever.
+func withRequestTimeout(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// httpStatusErrorHandler maps the gRPC status code returned by the GCD
+// service to the equivalent HTTP status (400 for InvalidArgument, 504 for
+// DeadlineExceeded, and so on) instead of grpc-gateway's default mapping,
+// so the marshaled error body and status line always agree.
+func httpStatusErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+	w.Header().Set("Content-Type", marshaler.ContentType(nil))
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	body, marshalErr := protojson.Marshal(st.Proto())
+	if marshalErr != nil {
+		http.Error(w, st.Message(), runtime.HTTPStatusFromCode(st.Code()))
+		return
+	}
+	_, _ = w.Write(body)
+}
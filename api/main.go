@@ -1,49 +1,154 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
 	"fmt"
+	"gcd-labs/cmd/run"
+	"gcd-labs/cmd/telemetry"
 	"gcd-labs/proto"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
-	"strconv"
+	"os"
+	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	gcdNetwork     = flag.String("gcd-network", "tcp", "network of the GCD gRPC service: tcp or unix")
+	gcdEndpoint    = flag.String("gcd-endpoint", "gcd-service:3000", "address (tcp) or socket path (unix) of the GCD gRPC service")
+	listenAddr     = flag.String("addr", ":3000", "address for the HTTP gateway to listen on")
+	certFile       = flag.String("cert", "", "path to the client TLS certificate")
+	keyFile        = flag.String("key", "", "path to the client TLS private key")
+	caFile         = flag.String("ca", "", "path to the CA certificate used to verify the GCD service")
+	serverName     = flag.String("server-name", "", "expected SAN of the GCD service certificate")
+	drainTimeout   = flag.Duration("drain-timeout", 10*time.Second, "how long to wait for in-flight requests to finish before forcing shutdown")
+	requestTimeout = flag.Duration("request-timeout", 5*time.Second, "deadline applied to each proxied GCD RPC")
 )
 
 func main() {
-	conn, err := grpc.Dial("gcd-service:3000", grpc.WithInsecure())
+	flag.Parse()
+
+	logger := slog.Default()
+
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), "gcd-gateway")
 	if err != nil {
-		log.Fatalf("Fial failed: %v", err)
+		log.Fatalf("Failed to set up telemetry: %v", err)
 	}
-	gcdClient := proto.NewGCDServiceClient(conn)
-
-	r := gin.Default()
-	r.GET("/gcd/:a/:b", func(c *gin.Context) {
-		// Parse parameters
-		a, err := strconv.ParseUint(c.Param("a"), 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameter A"})
-			return
+	defer func() {
+		if err := shutdownTelemetry(context.Background()); err != nil {
+			logger.Warn("failed to flush telemetry", "error", err)
 		}
-		b, err := strconv.ParseUint(c.Param("b"), 10, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parameter B"})
-			return
-		}
-		// Call GCD service
-		req := &proto.GCDRequest{A: a, B: b}
-		if res, err := gcdClient.Computer(c, req); err == nil {
-			c.JSON(http.StatusOK, gin.H{
-				"result": fmt.Sprint(res.Result),
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}()
+
+	dialOpt, err := dialOption()
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+
+	dialOpts := []grpc.DialOption{
+		dialOpt,
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if *gcdNetwork == "unix" {
+		dialOpts = append(dialOpts, grpc.WithContextDialer(unixDialer))
+	}
+
+	conn, err := grpc.Dial(*gcdEndpoint, dialOpts...)
+	if err != nil {
+		log.Fatalf("Failed to dial gcd-service: %v", err)
+	}
+
+	ctx := context.Background()
+	mux := runtime.NewServeMux(runtime.WithErrorHandler(httpStatusErrorHandler))
+	if err := proto.RegisterGCDServiceHandler(ctx, mux, conn); err != nil {
+		log.Fatalf("Failed to register GCD service handler: %v", err)
+	}
+
+	root := http.NewServeMux()
+	root.Handle("/v1/", withRequestTimeout(*requestTimeout, mux))
+	root.HandleFunc("/openapi", serveSwagger)
+	registerObservabilityRoutes(root, conn)
+	registerBatchRoute(root, conn)
+
+	handler := otelhttp.NewHandler(loggingMiddleware(logger, root), "gateway")
+	srv := &http.Server{Addr: *listenAddr, Handler: handler}
+
+	runCtx, g, stop := run.Context()
+	defer stop()
+
+	g.Go(func() error {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
 		}
+		return nil
+	})
+	g.Go(func() error {
+		<-runCtx.Done()
+		logger.Info("shutting down: draining in-flight requests", "timeout", *drainTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
 	})
 
-	// Run the server
-	if err := r.Run(":3000"); err != nil {
-		log.Fatalf("Failed to run server: %v", err)
+	if err := g.Wait(); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+// unixDialer dials the GCD service over a UNIX domain socket. It is wired in
+// via grpc.WithContextDialer when -gcd-network=unix so grpc.Dial's target is
+// treated as a socket path rather than a host:port.
+func unixDialer(ctx context.Context, addr string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+}
+
+// serveSwagger serves the OpenAPI document generated alongside the
+// grpc-gateway stubs from proto/gcd.swagger.json.
+func serveSwagger(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(proto.SwaggerJSON)
+}
+
+// dialOption builds the grpc.DialOption carrying the transport security for
+// the connection to the GCD service. Client certs are presented so the
+// service can authenticate the gateway (mTLS); the CA is used to verify the
+// service's own certificate and its SAN against serverName.
+func dialOption() (grpc.DialOption, error) {
+	if *certFile == "" && *keyFile == "" && *caFile == "" {
+		log.Printf("warning: dialing gcd-service without TLS, do not use this outside local development")
+		return grpc.WithInsecure(), nil
 	}
+
+	cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load client key pair: %w", err)
+	}
+
+	caCert, err := os.ReadFile(*caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA certificate %s", *caFile)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      caPool,
+		ServerName:   *serverName,
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// registerObservabilityRoutes wires Prometheus metrics and the liveness/
+// readiness probes onto mux. readyz calls the GCD service's grpc_health_v1
+// endpoint over conn so the gateway only reports ready once its upstream is.
+func registerObservabilityRoutes(mux *http.ServeMux, conn *grpc.ClientConn) {
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := healthpb.NewHealthClient(conn).Check(r.Context(), &healthpb.HealthCheckRequest{})
+		if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+			http.Error(w, "gcd-service not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// statusRecorder captures the status code written through it so middleware
+// can log it after the handler chain returns.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Flush forwards to the underlying ResponseWriter's Flush, if it has one.
+// Without this, embedding http.ResponseWriter as an interface only promotes
+// its own method set, so registerBatchRoute's `w.(http.Flusher)` check would
+// always fail through this wrapper and /gcd/batch would stop streaming.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// loggingMiddleware emits a structured log line per HTTP request with the
+// method, path, peer, latency, and status code, mirroring the per-RPC
+// logging the GCD service does in loggingUnaryInterceptor.
+func loggingMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		logger.InfoContext(r.Context(), "http request completed",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("peer", r.RemoteAddr),
+			slog.Duration("latency", time.Since(start)),
+			slog.Int("status", rec.status),
+		)
+	})
+}
@@ -0,0 +1,23 @@
+// Package run provides the signal handling and goroutine coordination shared
+// by the gcd-service and gateway binaries so that both shut down cleanly
+// instead of dropping in-flight requests on SIGINT/SIGTERM.
+package run
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Context returns a context canceled on SIGINT/SIGTERM together with an
+// errgroup derived from it: any goroutine added via the returned group that
+// returns an error cancels the context for the others. Callers must invoke
+// the returned stop func (typically via defer) to release the signal
+// notification.
+func Context() (context.Context, *errgroup.Group, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	g, ctx := errgroup.WithContext(ctx)
+	return ctx, g, stop
+}
@@ -0,0 +1,55 @@
+// Package telemetry configures the OpenTelemetry SDK shared by the
+// gcd-service and gateway binaries, so the otelgrpc/otelhttp instrumentation
+// wired into both actually produces and exports spans instead of silently
+// falling back to the global no-op provider.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Setup registers a TracerProvider for serviceName as the global provider
+// and installs the W3C trace-context/baggage propagators, then returns a
+// shutdown func that flushes and closes the exporter. Spans are exported via
+// OTLP/gRPC when OTEL_EXPORTER_OTLP_ENDPOINT is set, and to stdout otherwise
+// so local development still produces visible traces.
+func Setup(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("build span exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return otlptracegrpc.New(ctx)
+	}
+	return stdouttrace.New(stdouttrace.WithoutTimestamps())
+}
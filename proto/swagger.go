@@ -0,0 +1,10 @@
+package proto
+
+import _ "embed"
+
+// SwaggerJSON is the OpenAPI document generated alongside the grpc-gateway
+// stubs from gcd.proto. It's embedded rather than read from disk so serving
+// it doesn't depend on the process's working directory.
+//
+//go:embed gcd.swagger.json
+var SwaggerJSON []byte
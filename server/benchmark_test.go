@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"gcd-labs/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newBenchClient spins up the GCD service over an in-memory bufconn listener
+// and returns a client dialed against it, so the benchmarks below measure RPC
+// overhead rather than the network.
+func newBenchClient(b *testing.B) proto.GCDServiceClient {
+	b.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	proto.RegisterGCDServiceServer(s, &server{})
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	b.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		b.Fatalf("dial: %v", err)
+	}
+	b.Cleanup(func() { conn.Close() })
+
+	return proto.NewGCDServiceClient(conn)
+}
+
+// BenchmarkComputerUnary measures the unary Computer RPC, one call per pair.
+func BenchmarkComputerUnary(b *testing.B) {
+	client := newBenchClient(b)
+	ctx := context.Background()
+	req := &proto.GCDRequest{A: 270892, B: 58772}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Computer(ctx, req); err != nil {
+			b.Fatalf("Computer: %v", err)
+		}
+	}
+}
+
+// BenchmarkComputeStream measures the same workload amortized over a single
+// bidi stream, to quantify the throughput win the streaming RPC is meant to
+// justify over issuing b.N separate unary calls.
+func BenchmarkComputeStream(b *testing.B) {
+	client := newBenchClient(b)
+	req := &proto.GCDRequest{A: 270892, B: 58772}
+
+	stream, err := client.ComputeStream(context.Background())
+	if err != nil {
+		b.Fatalf("ComputeStream: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			if _, err := stream.Recv(); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := stream.Send(req); err != nil {
+			b.Fatalf("Send: %v", err)
+		}
+	}
+	_ = stream.CloseSend()
+
+	if err := <-done; err != nil {
+		b.Fatalf("Recv: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"gcd-labs/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGCD(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b       uint64
+		maxValue   uint64
+		wantResult uint64
+		wantCode   codes.Code
+	}{
+		{name: "basic pair", a: 48, b: 18, wantResult: 6},
+		{name: "zero and non-zero", a: 0, b: 9, wantResult: 9},
+		{name: "equal values", a: 7, b: 7, wantResult: 7},
+		{name: "both zero rejected", a: 0, b: 0, wantCode: codes.InvalidArgument},
+		{name: "within bound", a: 10, b: 5, maxValue: 10, wantResult: 5},
+		{name: "a exceeds bound", a: 11, b: 5, maxValue: 10, wantCode: codes.InvalidArgument},
+		{name: "b exceeds bound", a: 5, b: 11, maxValue: 10, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := *maxValue
+			*maxValue = tt.maxValue
+			defer func() { *maxValue = old }()
+
+			resp, err := gcd(&proto.GCDRequest{A: tt.a, B: tt.b})
+
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("gcd(%d, %d) code = %v, want %v", tt.a, tt.b, status.Code(err), tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("gcd(%d, %d) unexpected error: %v", tt.a, tt.b, err)
+			}
+			if resp.Result != tt.wantResult {
+				t.Fatalf("gcd(%d, %d) = %d, want %d", tt.a, tt.b, resp.Result, tt.wantResult)
+			}
+		})
+	}
+}
@@ -1,32 +1,212 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"gcd-labs/cmd/run"
+	"gcd-labs/cmd/telemetry"
 	"gcd-labs/proto"
+	"io"
 	"log"
+	"log/slog"
 	"net"
+	"os"
+	"time"
 
-	context "golang.org/x/net/context"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	network        = flag.String("network", "tcp", "network to listen on: tcp or unix")
+	endpoint       = flag.String("endpoint", ":3000", "address (tcp) or socket path (unix) to listen on")
+	certFile       = flag.String("cert", "", "path to the server TLS certificate")
+	keyFile        = flag.String("key", "", "path to the server TLS private key")
+	caFile         = flag.String("ca", "", "path to the CA certificate used to verify client certificates")
+	expectedClient = flag.String("expected-client-cn", "", "if set, the client certificate's SAN/CN must match this value")
+	drainTimeout   = flag.Duration("drain-timeout", 10*time.Second, "how long to wait for in-flight RPCs to finish before forcing shutdown")
+	maxValue       = flag.Uint64("max-value", 0, "if non-zero, the largest value accepted for either operand")
 )
 
 type server struct{}
 
 func main() {
-	lis, err := net.Listen("tcp", ":3000")
+	flag.Parse()
+
+	if *network == "unix" {
+		if err := os.RemoveAll(*endpoint); err != nil {
+			log.Fatalf("Failed to remove stale socket: %v", err)
+		}
+	}
+	lis, err := net.Listen(*network, *endpoint)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
+	if *network == "unix" {
+		defer os.RemoveAll(*endpoint)
+		if err := os.Chmod(*endpoint, 0o660); err != nil {
+			log.Fatalf("Failed to set socket permissions: %v", err)
+		}
+	}
+
+	logger := slog.Default()
+
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), "gcd-service")
+	if err != nil {
+		log.Fatalf("Failed to set up telemetry: %v", err)
+	}
+	defer func() {
+		if err := shutdownTelemetry(context.Background()); err != nil {
+			logger.Warn("failed to flush telemetry", "error", err)
+		}
+	}()
+
+	opts, err := serverOptions()
+	if err != nil {
+		log.Fatalf("Failed to configure TLS: %v", err)
+	}
+	opts = append(opts,
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor(logger)),
+	)
+
+	s := grpc.NewServer(opts...)
 	proto.RegisterGCDServiceServer(s, &server{})
 	reflection.Register(s)
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	registerHealthService(s)
+
+	ctx, g, stop := run.Context()
+	defer stop()
+
+	g.Go(func() error {
+		return s.Serve(lis)
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		logger.Info("shutting down: draining in-flight requests", "timeout", *drainTimeout)
+		drained := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-time.After(*drainTimeout):
+			logger.Warn("drain timeout exceeded, forcing shutdown")
+			s.Stop()
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+// serverOptions builds the grpc.ServerOption set for the configured transport
+// security. It requires and verifies client certificates (mTLS) whenever a CA
+// is supplied.
+func serverOptions() ([]grpc.ServerOption, error) {
+	if *certFile == "" && *keyFile == "" && *caFile == "" {
+		log.Printf("warning: running without TLS, do not use this outside local development")
+		return nil, nil
 	}
 
+	cert, err := tls.LoadX509KeyPair(*certFile, *keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server key pair: %w", err)
+	}
+
+	caCert, err := os.ReadFile(*caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA certificate: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse CA certificate %s", *caFile)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	if *expectedClient != "" {
+		tlsConfig.VerifyPeerCertificate = verifyClientIdentity(*expectedClient)
+	}
+
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
+// verifyClientIdentity returns a VerifyPeerCertificate callback that rejects
+// any client certificate whose SAN (or, failing that, CN) does not match
+// expected. The chain itself has already been verified against ClientCAs by
+// the time this runs.
+func verifyClientIdentity(expected string) func([][]byte, [][]*x509.Certificate) error {
+	return func(_ [][]byte, chains [][]*x509.Certificate) error {
+		for _, chain := range chains {
+			if len(chain) == 0 {
+				continue
+			}
+			leaf := chain[0]
+			for _, name := range leaf.DNSNames {
+				if name == expected {
+					return nil
+				}
+			}
+			if leaf.Subject.CommonName == expected {
+				return nil
+			}
+		}
+		return fmt.Errorf("client certificate identity does not match expected %q", expected)
+	}
 }
 
 func (s *server) Computer(ctx context.Context, r *proto.GCDRequest) (*proto.GCDResponse, error) {
+	return gcd(r)
+}
+
+// ComputeStream reads operand pairs off the stream and writes back the GCD of
+// each as soon as it's computed, so a client can amortize connection setup
+// over many computations instead of issuing one unary RPC per pair. Unlike
+// Computer, a validation failure on one pair does not abort the stream: it's
+// reported via GCDResponse.Error so the rest of the batch still completes.
+func (s *server) ComputeStream(stream proto.GCDService_ComputeStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := gcd(req)
+		if err != nil {
+			resp = &proto.GCDResponse{Error: status.Convert(err).Message()}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// gcd validates the operand pair and computes their greatest common divisor.
+func gcd(r *proto.GCDRequest) (*proto.GCDResponse, error) {
+	if r.A == 0 && r.B == 0 {
+		return nil, status.Error(codes.InvalidArgument, "a and b cannot both be 0")
+	}
+	if *maxValue != 0 && (r.A > *maxValue || r.B > *maxValue) {
+		return nil, status.Errorf(codes.InvalidArgument, "a and b must be <= %d", *maxValue)
+	}
+
 	a, b := r.A, r.B
 	for b != 0 {
 		a, b = b, a%b
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gcd-labs/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// registerHealthService registers the standard grpc_health_v1 service and
+// marks it (and the GCD service) as serving, so Kubernetes liveness/readiness
+// probes can check status natively instead of relying on reflection.
+func registerHealthService(s *grpc.Server) {
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus(proto.GCDService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+}
+
+// loggingUnaryInterceptor returns a grpc.UnaryServerInterceptor that emits a
+// structured log line per RPC with the method, peer, latency, status code,
+// and (for Computer) the GCD result.
+func loggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		fields := []any{
+			slog.String("method", info.FullMethod),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("code", status.Code(err).String()),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			fields = append(fields, slog.String("peer", p.Addr.String()))
+		}
+		if gr, ok := resp.(*proto.GCDResponse); ok {
+			fields = append(fields, slog.Uint64("result", gr.Result))
+		}
+
+		if err != nil {
+			logger.ErrorContext(ctx, "rpc failed", append(fields, slog.String("error", err.Error()))...)
+		} else {
+			logger.InfoContext(ctx, "rpc completed", fields...)
+		}
+		return resp, err
+	}
+}